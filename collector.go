@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const exporterNamespace = "mesos_exporter"
+
+var (
+	scrapeDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(exporterNamespace, "collector", "duration_seconds"),
+		"Duration of a collector scrape.",
+		[]string{"collector"}, nil,
+	)
+	scrapeSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(exporterNamespace, "collector", "success"),
+		"Whether a collector succeeded.",
+		[]string{"collector"}, nil,
+	)
+)
+
+// collectorOptions carries the runtime configuration a collector factory
+// needs to build itself. It is assembled once in main() from flags and
+// handed to every enabled factory.
+type collectorOptions struct {
+	httpClient           *httpClient
+	stateProvider        stateProvider
+	slaveAttributeLabels []string
+}
+
+type collectorFactory func(opts *collectorOptions) (prometheus.Collector, error)
+
+var (
+	factories      = map[string]collectorFactory{}
+	collectorFlags = map[string]*bool{}
+)
+
+// registerCollector wires up a --collector.<name>/--no-collector.<name> flag
+// pair for a collector and records its factory, mirroring node_exporter's
+// registerCollector pattern so operators can disable expensive collectors.
+func registerCollector(name string, isDefaultEnabled bool, factory collectorFactory) {
+	flagName := fmt.Sprintf("collector.%s", name)
+	flagHelp := fmt.Sprintf("Enable the %s collector", name)
+
+	defaultState := "disabled"
+	if isDefaultEnabled {
+		defaultState = "enabled"
+	}
+
+	flag := kingpin.Flag(flagName, fmt.Sprintf("%s (default: %s)", flagHelp, defaultState)).
+		Default(fmt.Sprintf("%v", isDefaultEnabled)).Bool()
+
+	collectorFlags[name] = flag
+	factories[name] = factory
+}
+
+// MesosCollector fans a single Prometheus scrape out to every enabled
+// collector and reports per-collector duration and success as its own
+// metrics, the same wrapper role node_exporter's NodeCollector plays.
+//
+// Every state-derived collector is built against stateRef rather than the
+// real stateProvider directly: before each Collect, MesosCollector points
+// stateRef at a fresh memoizingStateProvider wrapping the real one, so the
+// many collectors it fans out to share a single /state fetch per scrape
+// instead of each hitting the master independently.
+type MesosCollector struct {
+	Collectors        map[string]prometheus.Collector
+	stateRef          *stateProviderRef
+	realStateProvider stateProvider
+}
+
+// NewMesosCollector builds a collector for every registered name whose flag
+// is enabled, unless filters is non-empty, in which case only those names
+// are used (each of which must be a known, enabled collector).
+func NewMesosCollector(opts *collectorOptions, filters ...string) (*MesosCollector, error) {
+	enabled := map[string]bool{}
+	if len(filters) == 0 {
+		for name, flag := range collectorFlags {
+			enabled[name] = *flag
+		}
+	} else {
+		for _, name := range filters {
+			flag, ok := collectorFlags[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown collector: %s", name)
+			}
+			if !*flag {
+				return nil, fmt.Errorf("collector disabled: %s", name)
+			}
+			enabled[name] = true
+		}
+	}
+
+	realStateProvider := opts.stateProvider
+	if realStateProvider == nil {
+		realStateProvider = newStateProviderForMode(opts.httpClient, nil)
+	}
+
+	stateRef := &stateProviderRef{}
+	stateRef.set(realStateProvider)
+
+	// Collectors are built against the indirection, not the real provider,
+	// so MesosCollector.Collect can swap in a fresh per-scrape memoizer.
+	factoryOpts := *opts
+	factoryOpts.stateProvider = stateRef
+
+	collectors := map[string]prometheus.Collector{}
+	for name, on := range enabled {
+		if !on {
+			continue
+		}
+		c, err := factories[name](&factoryOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create collector %s: %s", name, err)
+		}
+		collectors[name] = c
+	}
+
+	// The streaming state provider doubles as a prometheus.Collector for
+	// mesos_exporter_stream_connected; it isn't behind a --collector.*
+	// flag like the others since it has nothing to do with --master.mode
+	// poll, so wire it in directly when it's the provider in use.
+	if streaming, ok := realStateProvider.(*streamingStateProvider); ok {
+		collectors["master-stream"] = streaming
+	}
+
+	return &MesosCollector{
+		Collectors:        collectors,
+		stateRef:          stateRef,
+		realStateProvider: realStateProvider,
+	}, nil
+}
+
+func (m *MesosCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- scrapeDurationDesc
+	ch <- scrapeSuccessDesc
+}
+
+func (m *MesosCollector) Collect(ch chan<- prometheus.Metric) {
+	// One /state fetch is shared by every collector in this scrape, however
+	// many of them run concurrently below.
+	m.stateRef.set(&memoizingStateProvider{underlying: m.realStateProvider})
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(m.Collectors))
+	for name, c := range m.Collectors {
+		go func(name string, c prometheus.Collector) {
+			defer wg.Done()
+			execute(name, c, ch)
+		}(name, c)
+	}
+	wg.Wait()
+}
+
+func execute(name string, c prometheus.Collector, ch chan<- prometheus.Metric) {
+	begin := time.Now()
+	success := 1.0
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.WithFields(log.Fields{
+					"collector": name,
+					"panic":     r,
+				}).Error("collector panicked during Collect")
+				success = 0
+			}
+		}()
+		c.Collect(ch)
+	}()
+
+	duration := time.Since(begin)
+
+	log.WithFields(log.Fields{
+		"collector":        name,
+		"duration_seconds": duration.Seconds(),
+		"success":          success == 1,
+	}).Debug("collector finished")
+
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds(), name)
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success, name)
+}
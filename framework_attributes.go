@@ -0,0 +1,89 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+// fixedFrameworkFields are always exported on mesos_framework_attributes,
+// regardless of --frameworkLabels; the flag only controls which arbitrary
+// framework labels[] keys are additionally promoted.
+var fixedFrameworkFields = []string{"webui_url", "principal", "role", "user"}
+
+var frameworkLabels = kingpin.Flag(
+	"frameworkLabels",
+	"Arbitrary framework labels[] keys to promote to Prometheus labels on mesos_framework_attributes (repeatable), in addition to the always-exported webui_url, principal, role, and user fields",
+).Strings()
+
+func init() {
+	registerCollector("master-framework-attributes", false, newFrameworkAttributesCollector)
+}
+
+// frameworkAttributesCollector promotes framework fields (webui_url,
+// principal, role, user) to Prometheus labels, plus the arbitrary framework
+// labels selected by the --frameworkLabels flag. This mirrors
+// slaveAttributesCollector so frameworks can be sliced by business
+// dimensions without relabeling in Prometheus.
+type frameworkAttributesCollector struct {
+	stateProvider stateProvider
+
+	normalisedLabels []string
+	labels           []string
+	attributes       *settableCounterVec
+}
+
+func newFrameworkAttributesCollector(opts *collectorOptions) (prometheus.Collector, error) {
+	var normalisedLabels []string
+	for _, label := range normaliseLabelList(*frameworkLabels) {
+		// fixedFrameworkFields are already in labels below; promoting them
+		// again here would build a *prometheus.Desc with duplicate label
+		// names.
+		if !stringInSlice(label, fixedFrameworkFields) {
+			normalisedLabels = append(normalisedLabels, label)
+		}
+	}
+	labels := append(append([]string{"framework"}, fixedFrameworkFields...), normalisedLabels...)
+
+	return &frameworkAttributesCollector{
+		stateProvider: opts.stateProvider,
+
+		normalisedLabels: normalisedLabels,
+		labels:           labels,
+		attributes:       counter("framework", "attributes", "Attributes and labels assigned to frameworks", labels...),
+	}, nil
+}
+
+func (c *frameworkAttributesCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		return
+	}
+
+	for _, f := range s.Frameworks {
+		frameworkAttributesExport := prometheus.Labels{
+			"framework": f.Name,
+			"webui_url": f.WebuiURL,
+			"principal": f.Principal,
+			"role":      f.Role,
+			"user":      f.User,
+		}
+		for _, label := range c.normalisedLabels {
+			frameworkAttributesExport[label] = ""
+		}
+		for _, l := range f.Labels {
+			normalisedLabel := normaliseLabel(l.Key)
+			if stringInSlice(normalisedLabel, c.normalisedLabels) {
+				frameworkAttributesExport[normalisedLabel] = l.Value
+			}
+		}
+		c.attributes.Set(1, getLabelValuesFromMap(frameworkAttributesExport, c.labels)...)
+	}
+
+	c.attributes.Collect(ch)
+}
+
+func (c *frameworkAttributesCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.attributes.Describe(ch)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// simpleLRU is a minimal fixed-capacity cache mapping keys to string values,
+// evicting the least recently used entry once capacity is exceeded. It lets
+// collectors remember a little bit of state across scrapes (e.g. the last
+// known state of a task id) without retaining history forever. Safe for
+// concurrent use: prometheus.Registry may invoke a collector's Collect from
+// overlapping scrapes.
+type simpleLRU struct {
+	mu sync.Mutex
+
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key   string
+	value string
+}
+
+func newSimpleLRU(capacity int) *simpleLRU {
+	return &simpleLRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *simpleLRU) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry).value, true
+}
+
+func (c *simpleLRU) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*lruEntry).value = value
+		return
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, value: value})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruEntry).key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestSimpleLRUGetSetMiss(t *testing.T) {
+	c := newSimpleLRU(2)
+
+	if _, ok := c.get("missing"); ok {
+		t.Fatal("expected a miss for a key never set")
+	}
+
+	c.set("a", "1")
+	if v, ok := c.get("a"); !ok || v != "1" {
+		t.Fatalf("got (%q, %v), want (1, true)", v, ok)
+	}
+}
+
+func TestSimpleLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newSimpleLRU(2)
+
+	c.set("a", "1")
+	c.set("b", "2")
+	c.set("c", "3") // evicts "a", the least recently used
+
+	if _, ok := c.get("a"); ok {
+		t.Fatal("expected \"a\" to have been evicted")
+	}
+	if v, ok := c.get("b"); !ok || v != "2" {
+		t.Fatalf("got (%q, %v), want (2, true)", v, ok)
+	}
+	if v, ok := c.get("c"); !ok || v != "3" {
+		t.Fatalf("got (%q, %v), want (3, true)", v, ok)
+	}
+}
+
+func TestSimpleLRUGetRefreshesRecency(t *testing.T) {
+	c := newSimpleLRU(2)
+
+	c.set("a", "1")
+	c.set("b", "2")
+	c.get("a")        // "a" is now more recently used than "b"
+	c.set("c", "3") // evicts "b", not "a"
+
+	if _, ok := c.get("b"); ok {
+		t.Fatal("expected \"b\" to have been evicted")
+	}
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected \"a\" to still be present")
+	}
+}
+
+func TestSimpleLRUSetOverwritesExistingKey(t *testing.T) {
+	c := newSimpleLRU(2)
+
+	c.set("a", "1")
+	c.set("a", "2")
+
+	if v, ok := c.get("a"); !ok || v != "2" {
+		t.Fatalf("got (%q, %v), want (2, true)", v, ok)
+	}
+	if c.ll.Len() != 1 {
+		t.Fatalf("got %d entries, want 1 after overwriting an existing key", c.ll.Len())
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 type (
 	slave struct {
+		ID         string                     `json:"id"`
 		PID        string                     `json:"pid"`
 		Hostname   string                     `json:"hostname"`
 		Used       resources                  `json:"used_resources"`
@@ -27,296 +28,285 @@ type (
 	}
 
 	framework struct {
+		ID        string              `json:"id"`
 		Active    bool                `json:"active"`
 		Tasks     []task              `json:"tasks"`
 		Completed []task              `json:"completed_tasks"`
 		Name      string              `json:"name"`
+		Role      string              `json:"role"`
+		WebuiURL  string              `json:"webui_url"`
+		Principal string              `json:"principal"`
+		User      string              `json:"user"`
+		Labels    []frameworkLabel    `json:"labels"`
 		Used      framework_resources `json:"used_resources"`
 		Offered   framework_resources `json:"offered_resources"`
 	}
 
+	frameworkLabel struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+
 	state struct {
 		Slaves     []slave     `json:"slaves"`
 		Frameworks []framework `json:"frameworks"`
 	}
-
-	masterCollector struct {
-		*httpClient
-		metrics map[prometheus.Collector]func(*state, prometheus.Collector)
-	}
 )
 
-func newMasterStateCollector(httpClient *httpClient, slaveAttributeLabels []string) prometheus.Collector {
+func init() {
+	registerCollector("master-state", true, newSlaveResourceCollector)
+	registerCollector("master-frameworks", true, newFrameworkResourceCollector)
+	registerCollector("master-attributes", false, newSlaveAttributesCollector)
+}
+
+// slaveResourceCollector reports per-slave resource gauges plus whether the
+// last scrape of the master /state endpoint succeeded.
+type slaveResourceCollector struct {
+	stateProvider stateProvider
+
+	cpusDesc            *prometheus.Desc
+	cpusUsedDesc        *prometheus.Desc
+	cpusUnreservedDesc  *prometheus.Desc
+	memDesc             *prometheus.Desc
+	memUsedDesc         *prometheus.Desc
+	memUnreservedDesc   *prometheus.Desc
+	diskDesc            *prometheus.Desc
+	diskUsedDesc        *prometheus.Desc
+	diskUnreservedDesc  *prometheus.Desc
+	portsDesc           *prometheus.Desc
+	portsUsedDesc       *prometheus.Desc
+	portsUnreservedDesc *prometheus.Desc
+	upDesc              *prometheus.Desc
+	scrapeErrors        prometheus.Counter
+}
+
+func newSlaveResourceCollector(opts *collectorOptions) (prometheus.Collector, error) {
 	labels := []string{"slave", "hostname"}
-	framework_labels := []string{"framework"}
-
-	metrics := map[prometheus.Collector]func(*state, prometheus.Collector){
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Total slave CPUs (fractional)",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "cpus",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Total.CPUs)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Used slave CPUs (fractional)",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "cpus_used",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Used.CPUs)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Unreserved slave CPUs (fractional)",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "cpus_unreserved",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Unreserved.CPUs)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Total slave memory in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "mem_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Total.Mem * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Used slave memory in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "mem_used_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Used.Mem * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Unreserved slave memory in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "mem_unreserved_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Unreserved.Mem * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Total slave disk space in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "disk_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Total.Disk * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Used slave disk space in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "disk_used_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Used.Disk * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Unreserved slave disk in bytes",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "disk_unreserved_bytes",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(s.Unreserved.Disk * 1024)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Total slave ports",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "ports",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				size := s.Total.Ports.size()
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(float64(size))
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Used slave ports",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "ports_used",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				size := s.Used.Ports.size()
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(float64(size))
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Unreserved slave ports",
-			Namespace: "mesos",
-			Subsystem: "slave",
-			Name:      "ports_unreserved",
-		}, labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, s := range st.Slaves {
-				size := s.Unreserved.Ports.size()
-				c.(*prometheus.GaugeVec).WithLabelValues(s.PID, s.Hostname).Set(float64(size))
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Active framework",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "active",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				var active float64 = 0
-				if f.Active {
-					active = 1
-				}
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(active)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework cpu used",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "cpu_used",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Used.CPUs)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework disk used",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "disk_used",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Used.Disk)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework memory used",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "mem_used",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Used.Mem)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework cpu offered",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "cpu_offered",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Offered.CPUs)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework mem offered",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "mem_offered",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Offered.Mem)
-			}
-		},
-		prometheus.NewGaugeVec(prometheus.GaugeOpts{
-			Help:      "Framework disk offered",
-			Namespace: "mesos",
-			Subsystem: "framework",
-			Name:      "disk_offered",
-		}, framework_labels): func(st *state, c prometheus.Collector) {
-			c.(*prometheus.GaugeVec).Reset()
-			for _, f := range st.Frameworks {
-				c.(*prometheus.GaugeVec).WithLabelValues(f.Name).Set(f.Offered.Disk)
-			}
-		},
-	}
 
-	if len(slaveAttributeLabels) > 0 {
-		normalisedAttributeLabels := normaliseLabelList(slaveAttributeLabels)
-		slaveAttributesLabelsExport := append(labels, normalisedAttributeLabels...)
+	return &slaveResourceCollector{
+		stateProvider: opts.stateProvider,
 
-		metrics[counter("slave", "attributes", "Attributes assigned to slaves", slaveAttributesLabelsExport...)] = func(st *state, c prometheus.Collector) {
-			for _, s := range st.Slaves {
-				slaveAttributesExport := prometheus.Labels{
-					"slave": s.PID,
-				}
+		cpusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "cpus"),
+			"Total slave CPUs (fractional)", labels, nil),
+		cpusUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "cpus_used"),
+			"Used slave CPUs (fractional)", labels, nil),
+		cpusUnreservedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "cpus_unreserved"),
+			"Unreserved slave CPUs (fractional)", labels, nil),
+		memDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "mem_bytes"),
+			"Total slave memory in bytes", labels, nil),
+		memUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "mem_used_bytes"),
+			"Used slave memory in bytes", labels, nil),
+		memUnreservedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "mem_unreserved_bytes"),
+			"Unreserved slave memory in bytes", labels, nil),
+		diskDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "disk_bytes"),
+			"Total slave disk space in bytes", labels, nil),
+		diskUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "disk_used_bytes"),
+			"Used slave disk space in bytes", labels, nil),
+		diskUnreservedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "disk_unreserved_bytes"),
+			"Unreserved slave disk in bytes", labels, nil),
+		portsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "ports"),
+			"Total slave ports", labels, nil),
+		portsUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "ports_used"),
+			"Used slave ports", labels, nil),
+		portsUnreservedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "slave", "ports_unreserved"),
+			"Unreserved slave ports", labels, nil),
+		upDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "master", "up"),
+			"Whether the last scrape of the master /state endpoint succeeded", nil, nil),
+		scrapeErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: exporterNamespace,
+			Name:      "scrape_errors_total",
+			Help:      "Total number of errors fetching or decoding the master /state endpoint",
+		}),
+	}, nil
+}
 
-				// User labels
-				for _, label := range normalisedAttributeLabels {
-					slaveAttributesExport[label] = ""
-				}
-				for key, value := range s.Attributes {
-					normalisedLabel := normaliseLabel(key)
-					if stringInSlice(normalisedLabel, normalisedAttributeLabels) {
-						if attribute, err := attributeString(value); err == nil {
-							slaveAttributesExport[normalisedLabel] = attribute
-						}
-					}
-				}
-				c.(*settableCounterVec).Set(1, getLabelValuesFromMap(slaveAttributesExport, slaveAttributesLabelsExport)...)
-			}
-		}
+func (c *slaveResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		c.scrapeErrors.Inc()
+		ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, 0)
+		ch <- c.scrapeErrors
+		return
 	}
 
-	return &masterCollector{
-		httpClient: httpClient,
-		metrics:    metrics,
+	ch <- prometheus.MustNewConstMetric(c.upDesc, prometheus.GaugeValue, 1)
+	ch <- c.scrapeErrors
+
+	for _, s := range s.Slaves {
+		ch <- prometheus.MustNewConstMetric(c.cpusDesc, prometheus.GaugeValue, s.Total.CPUs, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.cpusUsedDesc, prometheus.GaugeValue, s.Used.CPUs, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.cpusUnreservedDesc, prometheus.GaugeValue, s.Unreserved.CPUs, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.memDesc, prometheus.GaugeValue, s.Total.Mem*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.memUsedDesc, prometheus.GaugeValue, s.Used.Mem*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.memUnreservedDesc, prometheus.GaugeValue, s.Unreserved.Mem*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.diskDesc, prometheus.GaugeValue, s.Total.Disk*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.diskUsedDesc, prometheus.GaugeValue, s.Used.Disk*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.diskUnreservedDesc, prometheus.GaugeValue, s.Unreserved.Disk*1024, s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.portsDesc, prometheus.GaugeValue, float64(s.Total.Ports.size()), s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.portsUsedDesc, prometheus.GaugeValue, float64(s.Used.Ports.size()), s.PID, s.Hostname)
+		ch <- prometheus.MustNewConstMetric(c.portsUnreservedDesc, prometheus.GaugeValue, float64(s.Unreserved.Ports.size()), s.PID, s.Hostname)
 	}
 }
 
-func (c *masterCollector) Collect(ch chan<- prometheus.Metric) {
-	var s state
-	log.WithField("url", "/state").Debug("fetching URL")
-	c.fetchAndDecode("/state", &s)
+func (c *slaveResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpusDesc
+	ch <- c.cpusUsedDesc
+	ch <- c.cpusUnreservedDesc
+	ch <- c.memDesc
+	ch <- c.memUsedDesc
+	ch <- c.memUnreservedDesc
+	ch <- c.diskDesc
+	ch <- c.diskUsedDesc
+	ch <- c.diskUnreservedDesc
+	ch <- c.portsDesc
+	ch <- c.portsUsedDesc
+	ch <- c.portsUnreservedDesc
+	ch <- c.upDesc
+	c.scrapeErrors.Describe(ch)
+}
+
+// frameworkResourceCollector reports per-framework resource gauges.
+type frameworkResourceCollector struct {
+	stateProvider stateProvider
+
+	activeDesc      *prometheus.Desc
+	cpuUsedDesc     *prometheus.Desc
+	diskUsedDesc    *prometheus.Desc
+	memUsedDesc     *prometheus.Desc
+	cpuOfferedDesc  *prometheus.Desc
+	memOfferedDesc  *prometheus.Desc
+	diskOfferedDesc *prometheus.Desc
+}
+
+func newFrameworkResourceCollector(opts *collectorOptions) (prometheus.Collector, error) {
+	labels := []string{"framework"}
+
+	return &frameworkResourceCollector{
+		stateProvider: opts.stateProvider,
 
-	for c, set := range c.metrics {
-		set(&s, c)
-		c.Collect(ch)
+		activeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "active"),
+			"Active framework", labels, nil),
+		cpuUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "cpu_used"),
+			"Framework cpu used", labels, nil),
+		diskUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "disk_used"),
+			"Framework disk used", labels, nil),
+		memUsedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "mem_used"),
+			"Framework memory used", labels, nil),
+		cpuOfferedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "cpu_offered"),
+			"Framework cpu offered", labels, nil),
+		memOfferedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "mem_offered"),
+			"Framework mem offered", labels, nil),
+		diskOfferedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "framework", "disk_offered"),
+			"Framework disk offered", labels, nil),
+	}, nil
+}
+
+func (c *frameworkResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		return
+	}
+
+	for _, f := range s.Frameworks {
+		var active float64
+		if f.Active {
+			active = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.activeDesc, prometheus.GaugeValue, active, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.cpuUsedDesc, prometheus.GaugeValue, f.Used.CPUs, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.diskUsedDesc, prometheus.GaugeValue, f.Used.Disk, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.memUsedDesc, prometheus.GaugeValue, f.Used.Mem, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.cpuOfferedDesc, prometheus.GaugeValue, f.Offered.CPUs, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.memOfferedDesc, prometheus.GaugeValue, f.Offered.Mem, f.Name)
+		ch <- prometheus.MustNewConstMetric(c.diskOfferedDesc, prometheus.GaugeValue, f.Offered.Disk, f.Name)
 	}
 }
 
-func (c *masterCollector) Describe(ch chan<- *prometheus.Desc) {
-	for metric := range c.metrics {
-		metric.Describe(ch)
+func (c *frameworkResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeDesc
+	ch <- c.cpuUsedDesc
+	ch <- c.diskUsedDesc
+	ch <- c.memUsedDesc
+	ch <- c.cpuOfferedDesc
+	ch <- c.memOfferedDesc
+	ch <- c.diskOfferedDesc
+}
+
+// slaveAttributesCollector promotes slave attributes to Prometheus labels,
+// as selected by the --slaveAttributeLabels flag.
+type slaveAttributesCollector struct {
+	stateProvider stateProvider
+
+	normalisedAttributeLabels []string
+	labels                    []string
+	attributes                *settableCounterVec
+}
+
+func newSlaveAttributesCollector(opts *collectorOptions) (prometheus.Collector, error) {
+	normalisedAttributeLabels := normaliseLabelList(opts.slaveAttributeLabels)
+	labels := append([]string{"slave"}, normalisedAttributeLabels...)
+
+	return &slaveAttributesCollector{
+		stateProvider: opts.stateProvider,
+
+		normalisedAttributeLabels: normalisedAttributeLabels,
+		labels:                    labels,
+		attributes:                counter("slave", "attributes", "Attributes assigned to slaves", labels...),
+	}, nil
+}
+
+func (c *slaveAttributesCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		return
+	}
+
+	for _, s := range s.Slaves {
+		slaveAttributesExport := prometheus.Labels{
+			"slave": s.PID,
+		}
+		for _, label := range c.normalisedAttributeLabels {
+			slaveAttributesExport[label] = ""
+		}
+		for key, value := range s.Attributes {
+			normalisedLabel := normaliseLabel(key)
+			if stringInSlice(normalisedLabel, c.normalisedAttributeLabels) {
+				if attribute, err := attributeString(value); err == nil {
+					slaveAttributesExport[normalisedLabel] = attribute
+				}
+			}
+		}
+		c.attributes.Set(1, getLabelValuesFromMap(slaveAttributesExport, c.labels)...)
 	}
+
+	c.attributes.Collect(ch)
+}
+
+func (c *slaveAttributesCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.attributes.Describe(ch)
 }
 
 type ranges [][2]uint64
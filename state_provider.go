@@ -0,0 +1,65 @@
+package main
+
+import "sync"
+
+// stateProvider is the source of truth the state-derived collectors read
+// from. pollingStateProvider re-fetches /state on every call; in --master.mode
+// stream, streamingStateProvider instead serves a snapshot kept up to date by
+// a long-lived /api/v1 subscription.
+type stateProvider interface {
+	getState() (*state, error)
+}
+
+type pollingStateProvider struct {
+	*httpClient
+}
+
+func (p *pollingStateProvider) getState() (*state, error) {
+	var s state
+	if err := p.fetchAndDecode("/state", &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// memoizingStateProvider wraps another stateProvider so that however many
+// times getState is called on it, the underlying fetch happens at most
+// once. MesosCollector.Collect builds one of these per scrape so the
+// several collectors it fans out to share a single /state fetch instead of
+// each hitting the master independently.
+type memoizingStateProvider struct {
+	underlying stateProvider
+
+	once  sync.Once
+	state *state
+	err   error
+}
+
+func (m *memoizingStateProvider) getState() (*state, error) {
+	m.once.Do(func() {
+		m.state, m.err = m.underlying.getState()
+	})
+	return m.state, m.err
+}
+
+// stateProviderRef lets the stateProvider a collector reads from be swapped
+// out after the collector has already been built. Collectors are built
+// against a ref so MesosCollector.Collect can repoint it at a fresh
+// memoizingStateProvider at the start of every scrape.
+type stateProviderRef struct {
+	mu       sync.RWMutex
+	provider stateProvider
+}
+
+func (r *stateProviderRef) set(p stateProvider) {
+	r.mu.Lock()
+	r.provider = p
+	r.mu.Unlock()
+}
+
+func (r *stateProviderRef) getState() (*state, error) {
+	r.mu.RLock()
+	p := r.provider
+	r.mu.RUnlock()
+	return p.getState()
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var masterMode = kingpin.Flag(
+	"master.mode",
+	"How the master state is obtained: poll /state on every scrape, or maintain a live snapshot via the /api/v1 SUBSCRIBE stream",
+).Default("poll").Enum("poll", "stream")
+
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+var (
+	streamConnectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(exporterNamespace, "stream", "connected"),
+		"Whether the /api/v1 SUBSCRIBE stream to the master is currently connected",
+		nil, nil,
+	)
+)
+
+// operatorEvent is the subset of the Mesos v1 Operator API event envelope
+// this exporter understands. Fields not declared here are ignored when
+// decoding.
+type operatorEvent struct {
+	Type string `json:"type"`
+
+	Subscribed struct {
+		GetState struct {
+			GetAgents struct {
+				Agents []struct {
+					AgentInfo slave `json:"agent_info"`
+				} `json:"agents"`
+			} `json:"get_agents"`
+			GetFrameworks struct {
+				Frameworks []struct {
+					FrameworkInfo framework `json:"framework_info"`
+				} `json:"frameworks"`
+			} `json:"get_frameworks"`
+		} `json:"get_state"`
+	} `json:"subscribed"`
+
+	AgentAdded struct {
+		Agent struct {
+			AgentInfo slave `json:"agent_info"`
+		} `json:"agent"`
+	} `json:"agent_added"`
+
+	AgentRemoved struct {
+		AgentID struct {
+			Value string `json:"value"`
+		} `json:"agent_id"`
+	} `json:"agent_removed"`
+
+	FrameworkAdded struct {
+		Framework struct {
+			FrameworkInfo framework `json:"framework_info"`
+		} `json:"framework"`
+	} `json:"framework_added"`
+
+	FrameworkUpdated struct {
+		Framework struct {
+			FrameworkInfo framework `json:"framework_info"`
+		} `json:"framework"`
+	} `json:"framework_updated"`
+
+	FrameworkRemoved struct {
+		FrameworkInfo struct {
+			Name string `json:"name"`
+		} `json:"framework_info"`
+	} `json:"framework_removed"`
+
+	TaskAdded struct {
+		Task task `json:"task"`
+	} `json:"task_added"`
+
+	TaskUpdated struct {
+		FrameworkID struct {
+			Value string `json:"value"`
+		} `json:"framework_id"`
+		Status struct {
+			TaskID struct {
+				Value string `json:"value"`
+			} `json:"task_id"`
+			State     string  `json:"state"`
+			Reason    string  `json:"reason"`
+			Source    string  `json:"source"`
+			Timestamp float64 `json:"timestamp"`
+		} `json:"status"`
+	} `json:"task_updated"`
+}
+
+// maxCompletedTasksPerFramework bounds how many terminal tasks apply()
+// keeps in a framework's Completed list once a task is pruned out of
+// Tasks, mirroring the master's own completed-task retention so a
+// long-lived stream connection can't grow this unboundedly.
+const maxCompletedTasksPerFramework = 1000
+
+// appendCompleted appends t to completed, keeping at most the most recent
+// maxCompletedTasksPerFramework entries.
+func appendCompleted(completed []task, t task) []task {
+	completed = append(completed, t)
+	if len(completed) > maxCompletedTasksPerFramework {
+		completed = completed[len(completed)-maxCompletedTasksPerFramework:]
+	}
+	return completed
+}
+
+// streamingStateProvider keeps an in-memory state snapshot fed by a
+// long-lived /api/v1 SUBSCRIBE stream, instead of re-fetching the full
+// /state JSON on every Prometheus scrape. getState is read-locked, and
+// apply never mutates a snapshot already handed out by getState in place
+// (it always builds fresh slices before publishing), so collectors can
+// safely run concurrently with snapshot updates.
+type streamingStateProvider struct {
+	*httpClient
+
+	mu        sync.RWMutex
+	snapshot  state
+	connected bool
+}
+
+func newStreamingStateProvider(httpClient *httpClient) *streamingStateProvider {
+	return &streamingStateProvider{httpClient: httpClient}
+}
+
+// newStateProviderForMode builds the stateProvider selected by
+// --master.mode, starting the background /api/v1 subscription in stream
+// mode so the feature is actually reachable.
+func newStateProviderForMode(httpClient *httpClient, done <-chan struct{}) stateProvider {
+	if *masterMode == "stream" {
+		p := newStreamingStateProvider(httpClient)
+		p.Start(done)
+		return p
+	}
+	return &pollingStateProvider{httpClient: httpClient}
+}
+
+// Start subscribes to /api/v1 and applies events to the snapshot until done
+// is closed, reconnecting with exponential backoff on failure.
+func (p *streamingStateProvider) Start(done <-chan struct{}) {
+	go func() {
+		backoff := minReconnectBackoff
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			connectedAt := time.Now()
+			if err := p.subscribeOnce(done); err != nil {
+				log.WithError(err).Warn("master /api/v1 stream disconnected")
+			}
+			p.setConnected(false)
+
+			if time.Since(connectedAt) > maxReconnectBackoff {
+				backoff = minReconnectBackoff
+			}
+
+			select {
+			case <-done:
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+		}
+	}()
+}
+
+func (p *streamingStateProvider) subscribeOnce(done <-chan struct{}) error {
+	req, err := p.newRequest("POST", "/api/v1", bytes.NewReader([]byte(`{"type":"SUBSCRIBE"}`)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subscribe to /api/v1 failed: %s", resp.Status)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		record, err := readRecordIOFrame(r)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var ev operatorEvent
+		if err := json.Unmarshal(record, &ev); err != nil {
+			log.WithError(err).Warn("failed to decode /api/v1 event")
+			continue
+		}
+
+		p.apply(&ev)
+	}
+}
+
+// readRecordIOFrame reads a single Mesos RecordIO frame: an ASCII decimal
+// length, a newline, then that many bytes of message.
+func readRecordIOFrame(r *bufio.Reader) ([]byte, error) {
+	sizeLine, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := strconv.Atoi(string(bytes.TrimSpace([]byte(sizeLine))))
+	if err != nil {
+		return nil, fmt.Errorf("bad recordio frame size %q: %s", sizeLine, err)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	return buf, nil
+}
+
+// cloneState deep-copies the slices state holds (and each framework's own
+// task slices), so a mutation made while building the next snapshot can
+// never reach a *state a concurrent getState() caller is still ranging
+// over.
+func cloneState(s state) state {
+	clone := state{
+		Slaves:     append([]slave{}, s.Slaves...),
+		Frameworks: make([]framework, len(s.Frameworks)),
+	}
+	for i, f := range s.Frameworks {
+		clone.Frameworks[i] = f
+		clone.Frameworks[i].Tasks = append([]task{}, f.Tasks...)
+		clone.Frameworks[i].Completed = append([]task{}, f.Completed...)
+	}
+	return clone
+}
+
+// apply folds one /api/v1 event into the snapshot. It never mutates the
+// published p.snapshot's backing arrays in place: every branch builds a new
+// state (starting from a deep clone) and only swaps p.snapshot to it right
+// before returning, so a getState() caller holding the previous snapshot is
+// never affected by a concurrent apply.
+func (p *streamingStateProvider) apply(ev *operatorEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ev.Type == "SUBSCRIBED" {
+		var s state
+		for _, a := range ev.Subscribed.GetState.GetAgents.Agents {
+			s.Slaves = append(s.Slaves, a.AgentInfo)
+		}
+		for _, f := range ev.Subscribed.GetState.GetFrameworks.Frameworks {
+			s.Frameworks = append(s.Frameworks, f.FrameworkInfo)
+		}
+		p.snapshot = s
+		p.connected = true
+		return
+	}
+
+	s := cloneState(p.snapshot)
+
+	switch ev.Type {
+	case "AGENT_ADDED":
+		s.Slaves = append(s.Slaves, ev.AgentAdded.Agent.AgentInfo)
+
+	case "AGENT_REMOVED":
+		id := ev.AgentRemoved.AgentID.Value
+		slaves := make([]slave, 0, len(s.Slaves))
+		for _, sl := range s.Slaves {
+			if sl.ID != id {
+				slaves = append(slaves, sl)
+			}
+		}
+		s.Slaves = slaves
+
+	case "FRAMEWORK_ADDED":
+		s.Frameworks = append(s.Frameworks, ev.FrameworkAdded.Framework.FrameworkInfo)
+
+	case "FRAMEWORK_UPDATED":
+		updated := ev.FrameworkUpdated.Framework.FrameworkInfo
+		for i, f := range s.Frameworks {
+			if f.Name == updated.Name {
+				s.Frameworks[i] = updated
+				break
+			}
+		}
+
+	case "FRAMEWORK_REMOVED":
+		name := ev.FrameworkRemoved.FrameworkInfo.Name
+		frameworks := make([]framework, 0, len(s.Frameworks))
+		for _, f := range s.Frameworks {
+			if f.Name != name {
+				frameworks = append(frameworks, f)
+			}
+		}
+		s.Frameworks = frameworks
+
+	case "TASK_ADDED":
+		t := ev.TaskAdded.Task
+		for i, f := range s.Frameworks {
+			if f.ID != t.FrameworkID {
+				continue
+			}
+			alreadyKnown := false
+			for _, existing := range f.Tasks {
+				if existing.ID == t.ID {
+					alreadyKnown = true
+					break
+				}
+			}
+			if !alreadyKnown {
+				s.Frameworks[i].Tasks = append(append([]task{}, f.Tasks...), t)
+			}
+			break
+		}
+
+	case "TASK_UPDATED":
+		frameworkID := ev.TaskUpdated.FrameworkID.Value
+		taskID := ev.TaskUpdated.Status.TaskID.Value
+		newState := ev.TaskUpdated.Status.State
+		newStatus := taskStatus{
+			State:     newState,
+			Timestamp: ev.TaskUpdated.Status.Timestamp,
+			Reason:    ev.TaskUpdated.Status.Reason,
+			Source:    ev.TaskUpdated.Status.Source,
+		}
+		for fi, f := range s.Frameworks {
+			if f.ID != frameworkID {
+				continue
+			}
+			tasks := make([]task, 0, len(f.Tasks))
+			for _, t := range f.Tasks {
+				if t.ID == taskID {
+					t.State = newState
+					t.Statuses = append(append([]taskStatus{}, t.Statuses...), newStatus)
+					if isTerminalTaskState(newState) {
+						// Once a task reaches a terminal state it stops
+						// receiving updates, so move it out of Tasks (as the
+						// master itself does) instead of keeping it around
+						// in Tasks for the lifetime of the stream.
+						s.Frameworks[fi].Completed = appendCompleted(s.Frameworks[fi].Completed, t)
+						continue
+					}
+				}
+				tasks = append(tasks, t)
+			}
+			s.Frameworks[fi].Tasks = tasks
+			break
+		}
+	}
+
+	p.snapshot = s
+}
+
+func (p *streamingStateProvider) setConnected(connected bool) {
+	p.mu.Lock()
+	p.connected = connected
+	p.mu.Unlock()
+}
+
+func (p *streamingStateProvider) getState() (*state, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if !p.connected {
+		return nil, fmt.Errorf("master /api/v1 stream is not connected")
+	}
+
+	s := p.snapshot
+	return &s, nil
+}
+
+func (p *streamingStateProvider) Describe(ch chan<- *prometheus.Desc) {
+	ch <- streamConnectedDesc
+}
+
+func (p *streamingStateProvider) Collect(ch chan<- prometheus.Metric) {
+	p.mu.RLock()
+	connected := p.connected
+	p.mu.RUnlock()
+
+	var v float64
+	if connected {
+		v = 1
+	}
+	ch <- prometheus.MustNewConstMetric(streamConnectedDesc, prometheus.GaugeValue, v)
+}
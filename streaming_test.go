@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestReadRecordIOFrame(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("5\nhello6\nworld!"))
+
+	frame, err := readRecordIOFrame(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(frame) != "hello" {
+		t.Fatalf("got frame %q, want %q", frame, "hello")
+	}
+
+	frame, err = readRecordIOFrame(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(frame) != "world!" {
+		t.Fatalf("got frame %q, want %q", frame, "world!")
+	}
+
+	if _, err := readRecordIOFrame(r); err == nil {
+		t.Fatal("expected an error reading past the end of the stream")
+	}
+}
+
+func TestReadRecordIOFrameBadSize(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("notanumber\npayload"))
+	if _, err := readRecordIOFrame(r); err == nil {
+		t.Fatal("expected an error for a non-numeric frame size")
+	}
+}
+
+func TestCloneStateIsIndependent(t *testing.T) {
+	orig := state{
+		Slaves: []slave{{ID: "slave1"}},
+		Frameworks: []framework{
+			{ID: "fw1", Tasks: []task{{ID: "task1", State: "TASK_RUNNING"}}},
+		},
+	}
+
+	clone := cloneState(orig)
+	clone.Slaves[0].ID = "mutated"
+	clone.Frameworks[0].Tasks[0].State = "TASK_FINISHED"
+	clone.Frameworks = append(clone.Frameworks, framework{ID: "fw2"})
+
+	if orig.Slaves[0].ID != "slave1" {
+		t.Fatalf("mutating the clone's Slaves leaked into the original: %+v", orig.Slaves)
+	}
+	if orig.Frameworks[0].Tasks[0].State != "TASK_RUNNING" {
+		t.Fatalf("mutating the clone's Tasks leaked into the original: %+v", orig.Frameworks[0].Tasks)
+	}
+	if len(orig.Frameworks) != 1 {
+		t.Fatalf("appending to the clone's Frameworks leaked into the original: %+v", orig.Frameworks)
+	}
+}
+
+func TestStreamingStateProviderApplySubscribed(t *testing.T) {
+	p := newStreamingStateProvider(nil)
+
+	var ev operatorEvent
+	ev.Type = "SUBSCRIBED"
+	ev.Subscribed.GetState.GetAgents.Agents = []struct {
+		AgentInfo slave `json:"agent_info"`
+	}{{AgentInfo: slave{ID: "slave1", PID: "slave(1)@10.0.0.1:5051"}}}
+	ev.Subscribed.GetState.GetFrameworks.Frameworks = []struct {
+		FrameworkInfo framework `json:"framework_info"`
+	}{{FrameworkInfo: framework{ID: "fw1", Name: "marathon"}}}
+
+	p.apply(&ev)
+
+	s, err := p.getState()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(s.Slaves) != 1 || s.Slaves[0].ID != "slave1" {
+		t.Fatalf("got slaves %+v, want a single slave1", s.Slaves)
+	}
+	if len(s.Frameworks) != 1 || s.Frameworks[0].ID != "fw1" {
+		t.Fatalf("got frameworks %+v, want a single fw1", s.Frameworks)
+	}
+}
+
+func subscribe(p *streamingStateProvider) {
+	var ev operatorEvent
+	ev.Type = "SUBSCRIBED"
+	ev.Subscribed.GetState.GetFrameworks.Frameworks = []struct {
+		FrameworkInfo framework `json:"framework_info"`
+	}{{FrameworkInfo: framework{ID: "fw1", Name: "marathon"}}}
+	p.apply(&ev)
+}
+
+func TestStreamingStateProviderApplyTaskAdded(t *testing.T) {
+	p := newStreamingStateProvider(nil)
+	subscribe(p)
+
+	var ev operatorEvent
+	ev.Type = "TASK_ADDED"
+	ev.TaskAdded.Task = task{ID: "task1", FrameworkID: "fw1", State: "TASK_STAGING"}
+	p.apply(&ev)
+
+	s, _ := p.getState()
+	if len(s.Frameworks[0].Tasks) != 1 || s.Frameworks[0].Tasks[0].ID != "task1" {
+		t.Fatalf("got tasks %+v, want a single task1", s.Frameworks[0].Tasks)
+	}
+
+	// A duplicate TASK_ADDED for the same id must not be appended twice.
+	p.apply(&ev)
+	s, _ = p.getState()
+	if len(s.Frameworks[0].Tasks) != 1 {
+		t.Fatalf("got %d tasks after a duplicate TASK_ADDED, want 1", len(s.Frameworks[0].Tasks))
+	}
+}
+
+func TestStreamingStateProviderApplyTaskUpdatedPrunesTerminalTasks(t *testing.T) {
+	p := newStreamingStateProvider(nil)
+	subscribe(p)
+
+	var added operatorEvent
+	added.Type = "TASK_ADDED"
+	added.TaskAdded.Task = task{ID: "task1", FrameworkID: "fw1", State: "TASK_RUNNING"}
+	p.apply(&added)
+
+	before, _ := p.getState()
+
+	var updated operatorEvent
+	updated.Type = "TASK_UPDATED"
+	updated.TaskUpdated.FrameworkID.Value = "fw1"
+	updated.TaskUpdated.Status.TaskID.Value = "task1"
+	updated.TaskUpdated.Status.State = "TASK_FINISHED"
+	updated.TaskUpdated.Status.Reason = "REASON_COMMAND_EXECUTOR_FAILED"
+	updated.TaskUpdated.Status.Source = "SOURCE_EXECUTOR"
+	updated.TaskUpdated.Status.Timestamp = 123.0
+	p.apply(&updated)
+
+	// The earlier getState() result must not have been mutated in place.
+	if before.Frameworks[0].Tasks[0].State != "TASK_RUNNING" {
+		t.Fatalf("apply mutated a previously handed-out snapshot: %+v", before.Frameworks[0].Tasks[0])
+	}
+
+	after, _ := p.getState()
+	if len(after.Frameworks[0].Tasks) != 0 {
+		t.Fatalf("got %d tasks still live after reaching a terminal state, want 0", len(after.Frameworks[0].Tasks))
+	}
+	if len(after.Frameworks[0].Completed) != 1 {
+		t.Fatalf("got %d completed tasks, want 1", len(after.Frameworks[0].Completed))
+	}
+
+	completed := after.Frameworks[0].Completed[0]
+	if completed.State != "TASK_FINISHED" {
+		t.Fatalf("got completed task state %q, want TASK_FINISHED", completed.State)
+	}
+	if n := len(completed.Statuses); n == 0 {
+		t.Fatal("expected the terminal status to be appended to Statuses")
+	} else {
+		last := completed.Statuses[n-1]
+		if last.Reason != "REASON_COMMAND_EXECUTOR_FAILED" || last.Source != "SOURCE_EXECUTOR" || last.Timestamp != 123.0 {
+			t.Fatalf("got last status %+v, want reason/source/timestamp carried over from the update event", last)
+		}
+	}
+}
+
+func TestAppendCompletedCaps(t *testing.T) {
+	var completed []task
+	for i := 0; i < maxCompletedTasksPerFramework+10; i++ {
+		completed = appendCompleted(completed, task{ID: string(rune(i))})
+	}
+	if len(completed) != maxCompletedTasksPerFramework {
+		t.Fatalf("got %d completed tasks, want capped at %d", len(completed), maxCompletedTasksPerFramework)
+	}
+}
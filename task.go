@@ -0,0 +1,123 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+type task struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	State       string              `json:"state"`
+	SlaveID     string              `json:"slave_id"`
+	FrameworkID string              `json:"framework_id"`
+	Resources   framework_resources `json:"resources"`
+	Statuses    []taskStatus        `json:"statuses"`
+}
+
+// taskStatus is one entry of a task's status history, as reported in the
+// master /state JSON's statuses array.
+type taskStatus struct {
+	State     string  `json:"state"`
+	Timestamp float64 `json:"timestamp"`
+	Reason    string  `json:"reason"`
+	Source    string  `json:"source"`
+}
+
+var taskMaxTasks = kingpin.Flag(
+	"collector.tasks.max-tasks",
+	"Maximum number of tasks to export metrics for per scrape; 0 means unlimited",
+).Default("0").Int()
+
+func init() {
+	registerCollector("tasks", true, newTaskCollector)
+}
+
+// taskCollector emits per-task resource and state gauges by walking every
+// framework's active and completed tasks. Task counts on large Mesos
+// clusters can easily exceed Prometheus label-cardinality budgets, so the
+// number of tasks exported per scrape can be capped with
+// --collector.tasks.max-tasks; tasks dropped past the cap are counted in
+// mesos_exporter_tasks_dropped_total.
+type taskCollector struct {
+	stateProvider stateProvider
+
+	cpusDesc  *prometheus.Desc
+	memDesc   *prometheus.Desc
+	diskDesc  *prometheus.Desc
+	stateDesc *prometheus.Desc
+	dropped   prometheus.Counter
+}
+
+func newTaskCollector(opts *collectorOptions) (prometheus.Collector, error) {
+	taskLabels := []string{"task_id", "framework", "slave"}
+	stateLabels := []string{"task_id", "framework", "slave", "state", "role"}
+
+	return &taskCollector{
+		stateProvider: opts.stateProvider,
+
+		cpusDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "task", "cpus"),
+			"Task CPUs (fractional)", taskLabels, nil),
+		memDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "task", "mem_bytes"),
+			"Task memory in bytes", taskLabels, nil),
+		diskDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "task", "disk_bytes"),
+			"Task disk space in bytes", taskLabels, nil),
+		stateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName("mesos", "task", "state"),
+			"Task state; the gauge for a task's current state is set to 1", stateLabels, nil),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: exporterNamespace,
+			Name:      "tasks_dropped_total",
+			Help:      "Total number of tasks not exported because --collector.tasks.max-tasks was reached",
+		}),
+	}, nil
+}
+
+func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		return
+	}
+
+	// Maps slave ID to slave PID, the same value master_state.go's
+	// slave-resource collector uses for its own "slave" label, so task and
+	// slave metrics can be joined with on(slave).
+	slavePIDs := make(map[string]string, len(s.Slaves))
+	for _, sl := range s.Slaves {
+		slavePIDs[sl.ID] = sl.PID
+	}
+
+	var exported int
+	for _, f := range s.Frameworks {
+		for _, tasks := range [][]task{f.Tasks, f.Completed} {
+			for _, t := range tasks {
+				if *taskMaxTasks > 0 && exported >= *taskMaxTasks {
+					c.dropped.Inc()
+					continue
+				}
+				exported++
+
+				slave := slavePIDs[t.SlaveID]
+				ch <- prometheus.MustNewConstMetric(c.cpusDesc, prometheus.GaugeValue, t.Resources.CPUs, t.ID, f.Name, slave)
+				ch <- prometheus.MustNewConstMetric(c.memDesc, prometheus.GaugeValue, t.Resources.Mem*1024, t.ID, f.Name, slave)
+				ch <- prometheus.MustNewConstMetric(c.diskDesc, prometheus.GaugeValue, t.Resources.Disk*1024, t.ID, f.Name, slave)
+				ch <- prometheus.MustNewConstMetric(c.stateDesc, prometheus.GaugeValue, 1, t.ID, f.Name, slave, t.State, f.Role)
+			}
+		}
+	}
+
+	ch <- c.dropped
+}
+
+func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cpusDesc
+	ch <- c.memDesc
+	ch <- c.diskDesc
+	ch <- c.stateDesc
+	c.dropped.Describe(ch)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var taskLifecycleMaxTrackedTasks = kingpin.Flag(
+	"collector.task-lifecycle.max-tracked-tasks",
+	"Maximum number of task ids to remember across scrapes for terminal-transition detection",
+).Default("10000").Int()
+
+// terminalTaskStates are the Mesos task states from which a task cannot
+// transition further.
+var terminalTaskStates = map[string]bool{
+	"TASK_FINISHED":         true,
+	"TASK_FAILED":           true,
+	"TASK_KILLED":           true,
+	"TASK_LOST":             true,
+	"TASK_ERROR":            true,
+	"TASK_DROPPED":          true,
+	"TASK_GONE":             true,
+	"TASK_GONE_BY_OPERATOR": true,
+}
+
+func isTerminalTaskState(s string) bool {
+	return terminalTaskStates[s]
+}
+
+func init() {
+	registerCollector("task-lifecycle", true, newTaskLifecycleCollector)
+}
+
+// taskLifecycleCollector tracks task state transitions across scrapes to
+// report how long tasks run and how often they end up in each terminal
+// state. Unlike the other collectors in this package it is stateful: it
+// keeps a bounded LRU of the last known state per task id so a transition
+// into a terminal state is counted once while the id stays in the LRU. A task
+// first seen already terminal is counted as such a transition, and one whose
+// id was evicted from the LRU is counted again when seen again.
+type taskLifecycleCollector struct {
+	stateProvider stateProvider
+
+	duration *prometheus.HistogramVec
+	terminal *prometheus.CounterVec
+
+	lastState *simpleLRU
+}
+
+func newTaskLifecycleCollector(opts *collectorOptions) (prometheus.Collector, error) {
+	return &taskLifecycleCollector{
+		stateProvider: opts.stateProvider,
+
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mesos",
+			Subsystem: "task",
+			Name:      "duration_seconds",
+			Help:      "Task lifetime from first TASK_STARTING/TASK_RUNNING to a terminal status",
+		}, []string{"framework", "state"}),
+		terminal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mesos",
+			Name:      "tasks_terminal_total",
+			Help:      "Total number of tasks observed transitioning into a terminal state",
+		}, []string{"framework", "state", "reason", "source"}),
+
+		lastState: newSimpleLRU(*taskLifecycleMaxTrackedTasks),
+	}, nil
+}
+
+func (c *taskLifecycleCollector) Collect(ch chan<- prometheus.Metric) {
+	s, err := c.stateProvider.getState()
+	if err != nil {
+		log.WithError(err).Warn("failed to fetch and decode /state")
+		c.duration.Collect(ch)
+		c.terminal.Collect(ch)
+		return
+	}
+
+	for _, f := range s.Frameworks {
+		for _, tasks := range [][]task{f.Tasks, f.Completed} {
+			for _, t := range tasks {
+				c.observe(f.Name, t)
+			}
+		}
+	}
+
+	c.duration.Collect(ch)
+	c.terminal.Collect(ch)
+}
+
+func (c *taskLifecycleCollector) observe(frameworkName string, t task) {
+	previousState, known := c.lastState.get(t.ID)
+	wasTerminal := known && isTerminalTaskState(previousState)
+	isTerminal := isTerminalTaskState(t.State)
+
+	if isTerminal && !wasTerminal {
+		var reason, source string
+		if n := len(t.Statuses); n > 0 {
+			reason = t.Statuses[n-1].Reason
+			source = t.Statuses[n-1].Source
+		}
+		c.terminal.WithLabelValues(frameworkName, t.State, reason, source).Inc()
+
+		if start, end, ok := taskLifetime(t.Statuses); ok {
+			c.duration.WithLabelValues(frameworkName, t.State).Observe(end - start)
+		}
+	}
+
+	c.lastState.set(t.ID, t.State)
+}
+
+// taskLifetime returns the timestamps of a task's first
+// TASK_STARTING/TASK_RUNNING status and of its last (terminal) status; ok is
+// false when there is no such first status or the last one is not later.
+func taskLifetime(statuses []taskStatus) (start, end float64, ok bool) {
+	for _, st := range statuses {
+		if st.State == "TASK_STARTING" || st.State == "TASK_RUNNING" {
+			start = st.Timestamp
+			ok = true
+			break
+		}
+	}
+	if !ok || len(statuses) == 0 {
+		return 0, 0, false
+	}
+
+	end = statuses[len(statuses)-1].Timestamp
+	if end <= start {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
+func (c *taskLifecycleCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.duration.Describe(ch)
+	c.terminal.Describe(ch)
+}
@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestIsTerminalTaskState(t *testing.T) {
+	cases := map[string]bool{
+		"TASK_RUNNING":  false,
+		"TASK_STAGING":  false,
+		"TASK_FINISHED": true,
+		"TASK_FAILED":   true,
+		"TASK_LOST":     true,
+	}
+	for state, want := range cases {
+		if got := isTerminalTaskState(state); got != want {
+			t.Errorf("isTerminalTaskState(%q) = %v, want %v", state, got, want)
+		}
+	}
+}
+
+func TestTaskLifetime(t *testing.T) {
+	cases := []struct {
+		name      string
+		statuses  []taskStatus
+		wantOK    bool
+		wantStart float64
+		wantEnd   float64
+	}{
+		{
+			name:   "no statuses",
+			wantOK: false,
+		},
+		{
+			name: "never left staging",
+			statuses: []taskStatus{
+				{State: "TASK_STAGING", Timestamp: 1},
+			},
+			wantOK: false,
+		},
+		{
+			name: "running to finished",
+			statuses: []taskStatus{
+				{State: "TASK_STAGING", Timestamp: 1},
+				{State: "TASK_RUNNING", Timestamp: 2},
+				{State: "TASK_FINISHED", Timestamp: 5},
+			},
+			wantOK:    true,
+			wantStart: 2,
+			wantEnd:   5,
+		},
+		{
+			name: "starting to finished",
+			statuses: []taskStatus{
+				{State: "TASK_STARTING", Timestamp: 1},
+				{State: "TASK_FINISHED", Timestamp: 3},
+			},
+			wantOK:    true,
+			wantStart: 1,
+			wantEnd:   3,
+		},
+		{
+			name: "out of order timestamps are rejected",
+			statuses: []taskStatus{
+				{State: "TASK_RUNNING", Timestamp: 5},
+				{State: "TASK_FINISHED", Timestamp: 2},
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, ok := taskLifetime(tc.statuses)
+			if ok != tc.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if start != tc.wantStart || end != tc.wantEnd {
+				t.Fatalf("got (start=%v, end=%v), want (start=%v, end=%v)", start, end, tc.wantStart, tc.wantEnd)
+			}
+		})
+	}
+}